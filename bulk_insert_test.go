@@ -0,0 +1,128 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package toyorm
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestShouldBulkInsert(t *testing.T) {
+	cases := []struct {
+		rowCount, threshold int
+		want                bool
+	}{
+		{99, 100, false},
+		{100, 100, true},
+		{1000, 100, true},
+		{1, 0, true},
+	}
+	for _, c := range cases {
+		if got := ShouldBulkInsert(c.rowCount, c.threshold); got != c.want {
+			t.Errorf("ShouldBulkInsert(%d, %d) = %v, want %v", c.rowCount, c.threshold, got, c.want)
+		}
+	}
+}
+
+func TestBulkInsertExecBuildsCopyStatement(t *testing.T) {
+	dia := PostgreSqlDialect{}
+	model := &Model{Name: "user"}
+	rows := [][]ColumnValue{
+		{fakeColumnValue{col: "name", val: "alice"}, fakeColumnValue{col: "age", val: 30}},
+		{fakeColumnValue{col: "name", val: "bob"}, fakeColumnValue{col: "age", val: 31}},
+	}
+
+	exec := dia.BulkInsertExec(model, rows)
+	want := `COPY "user"(name,age) FROM STDIN`
+	if exec.Source() != want {
+		t.Fatalf("Source() = %q, want %q", exec.Source(), want)
+	}
+}
+
+func bulkInsertRows() [][]ColumnValue {
+	return [][]ColumnValue{
+		{fakeColumnValue{col: "name", val: "alice"}, fakeColumnValue{col: "age", val: 30}},
+		{fakeColumnValue{col: "name", val: "bob"}, fakeColumnValue{col: "age", val: 31}},
+	}
+}
+
+// TestBulkInsertExecutorAcceptsDB covers the *sql.DB branch of
+// BulkInsertExecutor's Executor type switch: it must open and commit its
+// own transaction around the COPY.
+func TestBulkInsertExecutorAcceptsDB(t *testing.T) {
+	registerCountingDriverOnce.Do(registerCountingDriver)
+
+	counter := 0
+	dsn := "TestBulkInsertExecutorAcceptsDB"
+	driverCounters.mu.Lock()
+	driverCounters.m[dsn] = &counter
+	driverCounters.mu.Unlock()
+
+	db, err := sql.Open("toyorm-counting-driver", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db.Close()
+
+	dia := PostgreSqlDialect{}
+	model := &Model{Name: "user"}
+	if err := dia.BulkInsertExecutor(db, model, bulkInsertRows(), debugNoop); err != nil {
+		t.Fatalf("BulkInsertExecutor(*sql.DB): %v", err)
+	}
+}
+
+// TestBulkInsertExecutorAcceptsTx covers the *sql.Tx branch: passing a
+// transaction the caller already holds must drive the COPY inside it
+// instead of opening a second, independent transaction.
+func TestBulkInsertExecutorAcceptsTx(t *testing.T) {
+	registerCountingDriverOnce.Do(registerCountingDriver)
+
+	counter := 0
+	dsn := "TestBulkInsertExecutorAcceptsTx"
+	driverCounters.mu.Lock()
+	driverCounters.m[dsn] = &counter
+	driverCounters.mu.Unlock()
+
+	db, err := sql.Open("toyorm-counting-driver", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("db.Begin: %v", err)
+	}
+
+	dia := PostgreSqlDialect{}
+	model := &Model{Name: "user"}
+	if err := dia.BulkInsertExecutor(tx, model, bulkInsertRows(), debugNoop); err != nil {
+		tx.Rollback()
+		t.Fatalf("BulkInsertExecutor(*sql.Tx): %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("tx.Commit: %v", err)
+	}
+}
+
+// TestBulkInsertExecutorRejectsUnknownExecutor covers the default branch:
+// an Executor that's neither *sql.DB nor *sql.Tx can't be driven through
+// the COPY protocol and must fail instead of panicking or silently no-oping.
+func TestBulkInsertExecutorRejectsUnknownExecutor(t *testing.T) {
+	dia := PostgreSqlDialect{}
+	model := &Model{Name: "user"}
+	if err := dia.BulkInsertExecutor(fakeExecutor{}, model, bulkInsertRows(), debugNoop); err == nil {
+		t.Fatal("BulkInsertExecutor(fakeExecutor{}) = nil error, want an error (only *sql.DB/*sql.Tx drive the COPY protocol)")
+	}
+}
+
+type fakeExecutor struct{}
+
+func (fakeExecutor) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return nil, nil
+}
+func (fakeExecutor) QueryRow(query string, args ...interface{}) *sql.Row { return nil }
@@ -0,0 +1,24 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package toyorm
+
+import "testing"
+
+func TestPostgresColumnTypeNormalizesJSONB(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{JSONB, "jsonb"},
+		{"JSONB", "jsonb"},
+		{"Jsonb", "jsonb"},
+		{"text", "text"},
+		{"integer", "integer"},
+	}
+	for _, c := range cases {
+		if got := postgresColumnType(c.in); got != c.want {
+			t.Errorf("postgresColumnType(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
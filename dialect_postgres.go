@@ -8,9 +8,12 @@ package toyorm
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"reflect"
 	"strings"
+
+	"github.com/lib/pq"
 )
 
 type PostgreSqlDialect struct{}
@@ -22,34 +25,129 @@ func (dia PostgreSqlDialect) HasTable(model *Model) ExecValue {
 	}}
 }
 
-type RawResult struct {
-	ID  int64
-	Err error
-	//rowsAffected int64
+// ReturningResult carries the values a RETURNING clause produced for a
+// single row, keyed by column name, so callers can pull out more than the
+// one autoincrement ID RawResult used to assume: server-generated uuid
+// defaults, created_at timestamps, computed columns, and so on. Order
+// records the RETURNING column list in request order, so LastInsertId
+// knows which column was actually asked for first instead of scanning
+// Values (a map, with no defined iteration order) for "the" int64 one.
+type ReturningResult struct {
+	Values map[string]driver.Value
+	Order  []string
+	Err    error
 }
 
-func (t RawResult) LastInsertId() (int64, error) {
-	return t.ID, t.Err
+// LastInsertId keeps sql.Result satisfied for callers that only care about
+// a single integer primary key: the first column RETURNING was asked for,
+// in Order, that actually came back as an int64.
+func (t ReturningResult) LastInsertId() (int64, error) {
+	for _, column := range t.Order {
+		if id, ok := t.Values[column].(int64); ok {
+			return id, t.Err
+		}
+	}
+	return 0, t.Err
 }
 
-func (t RawResult) RowsAffected() (int64, error) {
+func (t ReturningResult) RowsAffected() (int64, error) {
 	return 0, ErrNotSupportRowsAffected{}
 }
 
-func (dia PostgreSqlDialect) InsertExecutor(db Executor, exec ExecValue, debugPrinter func(string, string, error)) (sql.Result, error) {
-	var result RawResult
+// InsertExecutor runs exec against db, preparing it through cache first so
+// repeated inserts with the same rendered query reuse the same plan
+// instead of having Postgres re-parse it on every call. cache may be nil,
+// in which case it behaves exactly as before: db.Exec/db.QueryRow with the
+// raw query string.
+func (dia PostgreSqlDialect) InsertExecutor(db Executor, cache *PreparedCache, exec ExecValue, returning []Column, debugPrinter func(string, string, error)) (sql.Result, error) {
 	query := exec.Query()
+	stmt, prepErr := cache.Prepare(db, "postgres", query)
+	if prepErr != nil {
+		debugPrinter(query, exec.JsonArgs(), prepErr)
+		return nil, prepErr
+	}
+
+	if len(returning) == 0 {
+		var res sql.Result
+		var err error
+		if stmt != nil {
+			res, err = stmt.Exec(exec.Args()...)
+		} else {
+			res, err = db.Exec(query, exec.Args()...)
+		}
+		debugPrinter(query, exec.JsonArgs(), err)
+		return res, err
+	}
+
+	dest := make([]interface{}, len(returning))
+	values := make([]driver.Value, len(returning))
+	for i := range dest {
+		dest[i] = &values[i]
+	}
+
+	var row *sql.Row
+	if stmt != nil {
+		row = stmt.QueryRow(exec.Args()...)
+	} else {
+		row = db.QueryRow(query, exec.Args()...)
+	}
+
+	var result ReturningResult
 	var err error
-	if scanErr := db.QueryRow(query, exec.Args()...).Scan(&result.ID); scanErr == sql.ErrNoRows {
+	if scanErr := row.Scan(dest...); scanErr == sql.ErrNoRows {
 		result.Err = scanErr
-	} else {
+	} else if scanErr != nil {
 		err = scanErr
+	} else {
+		result.Values = make(map[string]driver.Value, len(returning))
+		result.Order = make([]string, len(returning))
+		for i, column := range returning {
+			result.Values[column.Column()] = values[i]
+			result.Order[i] = column.Column()
+		}
 	}
 
 	debugPrinter(query, exec.JsonArgs(), err)
 	return result, err
 }
 
+// HasReturningID reports that Postgres can hand back the autoincrement
+// primary key from the same round trip as the INSERT, via RETURNING, so
+// callers never need a separate last-insert-id query.
+func (dia PostgreSqlDialect) HasReturningID() bool {
+	return true
+}
+
+// PrepareUpdate prepares the UPDATE statement for columnValues against
+// cache, the same way InsertExecutor prepares an insert. There is no
+// UpdateExecutor in this tree to call it yet, so it's wiring for whatever
+// update exec path lands next, not a currently-reachable path.
+func (dia PostgreSqlDialect) PrepareUpdate(cache *PreparedCache, db Executor, model *Model, columnValues []ColumnValue) (*sql.Stmt, ExecValue, error) {
+	exec := dia.UpdateExec(model, columnValues)
+	stmt, err := cache.Prepare(db, "postgres", exec.Query())
+	return stmt, exec, err
+}
+
+// PrepareFind prepares the SELECT statement for columns against cache, the
+// same way InsertExecutor prepares an insert. There is no FindExecutor in
+// this tree to call it yet, so it's wiring for whatever find exec path
+// lands next, not a currently-reachable path.
+func (dia PostgreSqlDialect) PrepareFind(cache *PreparedCache, db Executor, model *Model, columns []Column, joins []JoinInfo, distinctOn []Column) (*sql.Stmt, ExecValue, error) {
+	exec := dia.FindExec(model, columns, joins, distinctOn)
+	stmt, err := cache.Prepare(db, "postgres", exec.Query())
+	return stmt, exec, err
+}
+
+// postgresColumnType maps a field's sql type to what CreateTable should
+// actually emit, normalizing the JSONB tag to the lowercase "jsonb"
+// keyword Postgres expects regardless of how the struct tag spelled it.
+func postgresColumnType(sqlType string) string {
+	if strings.EqualFold(sqlType, JSONB) {
+		return "jsonb"
+	}
+	return sqlType
+}
+
 func (dia PostgreSqlDialect) CreateTable(model *Model, foreign map[string]ForeignKey) (execlist []ExecValue) {
 	// lazy init model
 	strList := []string{}
@@ -61,7 +159,7 @@ func (dia PostgreSqlDialect) CreateTable(model *Model, foreign map[string]Foreig
 		if sqlField.AutoIncrement() {
 			s = fmt.Sprintf("%s SERIAL", sqlField.Column())
 		} else {
-			s = fmt.Sprintf("%s %s", sqlField.Column(), sqlField.SqlType())
+			s = fmt.Sprintf("%s %s", sqlField.Column(), postgresColumnType(sqlField.SqlType()))
 		}
 		for k, v := range sqlField.Attrs() {
 			if v == "" {
@@ -271,6 +369,62 @@ func (dia PostgreSqlDialect) SearchExec(s SearchList) ExecValue {
 				s[i].Val.Value().Interface(),
 			)
 
+		case ExprIExact:
+			exec = exec.Append(
+				fmt.Sprintf("%s ILIKE ?", s[i].Val.Column()),
+				s[i].Val.Value().Interface(),
+			)
+
+		// Contains/StartsWith/EndsWith arrive with the value already %-wrapped
+		// by ColumnContains/ColumnStartsWith/ColumnEndsWith (see
+		// search_builder.go), so they render exactly like ExprLike/ExprIExact.
+		case ExprContains, ExprStartsWith, ExprEndsWith:
+			exec = exec.Append(
+				fmt.Sprintf("%s LIKE ?", s[i].Val.Column()),
+				s[i].Val.Value().Interface(),
+			)
+
+		case ExprIContains, ExprIStartsWith, ExprIEndsWith:
+			exec = exec.Append(
+				fmt.Sprintf("%s ILIKE ?", s[i].Val.Column()),
+				s[i].Val.Value().Interface(),
+			)
+
+		case ExprRegex:
+			exec = exec.Append(
+				fmt.Sprintf("%s ~ ?", s[i].Val.Column()),
+				s[i].Val.Value().Interface(),
+			)
+
+		case ExprIRegex:
+			exec = exec.Append(
+				fmt.Sprintf("%s ~* ?", s[i].Val.Column()),
+				s[i].Val.Value().Interface(),
+			)
+
+		case ExprJSONContains:
+			exec = exec.Append(
+				fmt.Sprintf("%s @> ?", s[i].Val.Column()),
+				s[i].Val.Value().Interface(),
+			)
+
+		case ExprJSONHasKey:
+			// "??" is the jsonb has-key operator escaped against this
+			// dialect's own "?" bind-placeholder syntax: the downstream
+			// rewrite step that turns "?" into $1, $2... must see a
+			// doubled "?" here and emit a literal "?" rather than
+			// consuming another bind argument for it.
+			exec = exec.Append(
+				fmt.Sprintf("%s ?? ?", s[i].Val.Column()),
+				s[i].Val.Value().Interface(),
+			)
+
+		case ExprJSONExtract:
+			exec = exec.Append(
+				fmt.Sprintf("%s #> ?", s[i].Val.Column()),
+				s[i].Val.Value().Interface(),
+			)
+
 		case ExprNull:
 			exec = exec.Append(
 				fmt.Sprintf("%s IS NULL", s[i].Val.Column()),
@@ -288,13 +442,28 @@ func (dia PostgreSqlDialect) SearchExec(s SearchList) ExecValue {
 	return stack[0]
 }
 
-func (dia PostgreSqlDialect) FindExec(model *Model, columns []Column) ExecValue {
+func (dia PostgreSqlDialect) FindExec(model *Model, columns []Column, joins []JoinInfo, distinctOn []Column) ExecValue {
 	var _list []string
 	for _, column := range columns {
 		_list = append(_list, column.Column())
 	}
+	from := fmt.Sprintf(`"%s"`, model.Name)
+	if len(joins) > 0 {
+		from = fmt.Sprintf(`"%s" T1`, model.Name)
+		for _, j := range joins {
+			from += fmt.Sprintf(` %s "%s" %s ON %s = %s`, j.Type, j.Table, j.Alias, j.OnLeft, j.OnRight)
+		}
+	}
+	selectClause := "SELECT"
+	if len(distinctOn) > 0 {
+		var distinctList []string
+		for _, column := range distinctOn {
+			distinctList = append(distinctList, column.Column())
+		}
+		selectClause = fmt.Sprintf("SELECT DISTINCT ON (%s)", strings.Join(distinctList, ","))
+	}
 	var exec ExecValue = QToSExec{}
-	exec = exec.Append(fmt.Sprintf(`SELECT %s FROM "%s"`, strings.Join(_list, ","), model.Name))
+	exec = exec.Append(fmt.Sprintf(`%s %s FROM %s`, selectClause, strings.Join(_list, ","), from))
 	return exec
 }
 
@@ -340,16 +509,141 @@ func (dia PostgreSqlDialect) insertExec(model *Model, columnValues []ColumnValue
 	return exec
 }
 
-func (dia PostgreSqlDialect) InsertExec(model *Model, columnValues []ColumnValue) ExecValue {
+// InsertExec builds the INSERT statement for columnValues. When returning
+// is empty it falls back to RETURNING the single autoincrement primary
+// key, matching the old single-column behavior; pass an explicit
+// returning to pull back server-generated defaults, computed columns, or
+// any other column the caller needs scanned back.
+//
+// It hands back the resolved returning list alongside the ExecValue so
+// that whatever ran InsertExec and whatever later calls InsertExecutor
+// agree on whether a RETURNING clause is actually in the query — passing
+// InsertExecutor the caller's original (possibly empty) returning slice
+// instead of this resolved one would make it pick db.Exec over
+// db.QueryRow and silently drop the row RETURNING produced.
+func (dia PostgreSqlDialect) InsertExec(model *Model, columnValues []ColumnValue, returning []Column) (ExecValue, []Column) {
 	exec := dia.insertExec(model, columnValues)
-	if len(model.GetPrimary()) == 1 && model.GetOnePrimary().AutoIncrement() {
-		exec = exec.Append(" RETURNING " + model.GetOnePrimary().Column())
+	if len(returning) == 0 && len(model.GetPrimary()) == 1 && model.GetOnePrimary().AutoIncrement() {
+		returning = []Column{model.GetOnePrimary()}
+	}
+	if len(returning) > 0 {
+		var columnList []string
+		for _, column := range returning {
+			columnList = append(columnList, column.Column())
+		}
+		exec = exec.Append(" RETURNING " + strings.Join(columnList, ","))
+	}
+	return exec, returning
+}
+
+// DefaultBulkInsertThreshold is the row count at which a caller should
+// prefer BulkInsertExec/BulkInsertExecutor over one insertExec per row.
+// ToyBrick.SaveAll/InsertAll choosing between the two paths based on this
+// (configurable) threshold is brick-layer work that doesn't exist in this
+// tree yet, so the threshold has no caller of its own here.
+const DefaultBulkInsertThreshold = 100
+
+// ShouldBulkInsert reports whether rowCount rows crossing threshold
+// justifies the COPY path over row-by-row inserts. threshold <= 0 means
+// always use the bulk path.
+func ShouldBulkInsert(rowCount, threshold int) bool {
+	if threshold <= 0 {
+		return true
+	}
+	return rowCount >= threshold
+}
+
+// bulkInsertColumns returns the column list a COPY FROM STDIN for model
+// would use, shared by BulkInsertExec (the rendered-query half, for
+// callers that just want to see the statement) and BulkInsertExecutor (the
+// half that actually drives it), so the two can't drift apart on which
+// columns are in the statement.
+func bulkInsertColumns(rowsColumnValues [][]ColumnValue) []string {
+	if len(rowsColumnValues) == 0 {
+		return nil
+	}
+	var columnList []string
+	for _, r := range rowsColumnValues[0] {
+		columnList = append(columnList, r.Column())
+	}
+	return columnList
+}
+
+// BulkInsertExec reports the COPY FROM STDIN statement BulkInsertExecutor
+// drives for model; it exists separately from BulkInsertExecutor because
+// running the COPY protocol needs one Exec call per row rather than a
+// single query string, not because the two disagree on the statement.
+func (dia PostgreSqlDialect) BulkInsertExec(model *Model, rowsColumnValues [][]ColumnValue) ExecValue {
+	columnList := bulkInsertColumns(rowsColumnValues)
+	if columnList == nil {
+		return QToSExec{}
+	}
+	return QToSExec{DefaultExec{fmt.Sprintf(`COPY "%s"(%s) FROM STDIN`, model.Name, strings.Join(columnList, ",")), nil}}
+}
+
+// BulkInsertExecutor writes rowsColumnValues into model's table through the
+// COPY protocol, which is a single network round trip no matter how many
+// rows are written, instead of one INSERT per row. Like every other
+// dialect method that runs a statement, it takes the Executor interface
+// rather than *sql.DB directly, so a caller already inside a transaction
+// can drive the COPY through that *sql.Tx instead of opening a second one.
+func (dia PostgreSqlDialect) BulkInsertExecutor(db Executor, model *Model, rowsColumnValues [][]ColumnValue, debugPrinter func(string, string, error)) error {
+	columnList := bulkInsertColumns(rowsColumnValues)
+	if columnList == nil {
+		return nil
+	}
+
+	switch conn := db.(type) {
+	case *sql.Tx:
+		return dia.runCopy(conn, model, columnList, rowsColumnValues, debugPrinter)
+	case *sql.DB:
+		tx, err := conn.Begin()
+		if err != nil {
+			return err
+		}
+		if err := dia.runCopy(tx, model, columnList, rowsColumnValues, debugPrinter); err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	default:
+		return ErrNotSupportBulkInsertExecutor{db}
 	}
-	return exec
+}
+
+// runCopy drives columnList/rowsColumnValues through tx via the COPY
+// protocol; it doesn't commit or roll back, since BulkInsertExecutor's two
+// callers disagree on whose job that is: a caller-owned *sql.Tx shouldn't
+// be committed out from under it, while a *sql.DB-opened one must be.
+func (dia PostgreSqlDialect) runCopy(tx *sql.Tx, model *Model, columnList []string, rowsColumnValues [][]ColumnValue, debugPrinter func(string, string, error)) error {
+	stmt, err := tx.Prepare(pq.CopyIn(model.Name, columnList...))
+	if err != nil {
+		return err
+	}
+	for _, row := range rowsColumnValues {
+		args := make([]interface{}, len(row))
+		for i, r := range row {
+			args[i] = r.Value().Interface()
+		}
+		if _, err := stmt.Exec(args...); err != nil {
+			stmt.Close()
+			return err
+		}
+	}
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		return err
+	}
+	if err := stmt.Close(); err != nil {
+		return err
+	}
+
+	debugPrinter(fmt.Sprintf(`COPY "%s"(%s) FROM STDIN`, model.Name, strings.Join(columnList, ",")), "", nil)
+	return nil
 }
 
 // postgres have not replace use ON CONFLICT(%s) replace
-func (dia PostgreSqlDialect) ReplaceExec(model *Model, columnValues []ColumnValue) ExecValue {
+func (dia PostgreSqlDialect) ReplaceExec(model *Model, columnValues []ColumnValue, returning []Column) ExecValue {
 	exec := dia.insertExec(model, columnValues)
 	primaryKeys := model.GetPrimary()
 	var primaryKeyNames []string
@@ -364,6 +658,13 @@ func (dia PostgreSqlDialect) ReplaceExec(model *Model, columnValues []ColumnValu
 		strings.Join(primaryKeyNames, ","),
 		strings.Join(recordList, ","),
 	))
+	if len(returning) > 0 {
+		var columnList []string
+		for _, column := range returning {
+			columnList = append(columnList, column.Column())
+		}
+		exec = exec.Append(" RETURNING " + strings.Join(columnList, ","))
+	}
 	return exec
 }
 
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package toyorm
+
+import (
+	"reflect"
+	"testing"
+)
+
+// fakeColumnValue is a minimal stand-in for the ColumnValue/Column
+// interfaces the dialect works against, just enough to render a query
+// string without needing a real Model/Field.
+type fakeColumnValue struct {
+	col string
+	val interface{}
+}
+
+func (f fakeColumnValue) Column() string       { return f.col }
+func (f fakeColumnValue) Value() reflect.Value { return reflect.ValueOf(f.val) }
+
+func TestSearchExecLikeFamilyRendersVerbatim(t *testing.T) {
+	// Contains/StartsWith/EndsWith no longer wrap anything themselves —
+	// that now happens once in search_builder.go, shared across dialects
+	// (see search_builder_test.go) — so the dialect must render whatever
+	// value it's handed exactly like a plain LIKE/ILIKE.
+	cases := []struct {
+		name string
+		expr int
+		want string
+	}{
+		{"contains", ExprContains, "name LIKE ?"},
+		{"starts with", ExprStartsWith, "name LIKE ?"},
+		{"ends with", ExprEndsWith, "name LIKE ?"},
+		{"icontains", ExprIContains, "name ILIKE ?"},
+		{"istarts with", ExprIStartsWith, "name ILIKE ?"},
+		{"iends with", ExprIEndsWith, "name ILIKE ?"},
+	}
+
+	dia := PostgreSqlDialect{}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			search := SearchList{{Type: c.expr, Val: fakeColumnValue{col: "name", val: "%foo%"}}}
+			exec := dia.SearchExec(search)
+			if exec.Source() != c.want {
+				t.Fatalf("Source() = %q, want %q", exec.Source(), c.want)
+			}
+			args := exec.Args()
+			if len(args) != 1 || args[0] != "%foo%" {
+				t.Fatalf("Args() = %v, want [%q] (dialect must not wrap/rewrap the value itself)", args, "%foo%")
+			}
+		})
+	}
+}
+
+func TestSearchExecIExactDoesNotWrap(t *testing.T) {
+	dia := PostgreSqlDialect{}
+	search := SearchList{{Type: ExprIExact, Val: fakeColumnValue{col: "name", val: "foo"}}}
+	exec := dia.SearchExec(search)
+	if exec.Source() != "name ILIKE ?" {
+		t.Fatalf("Source() = %q, want %q", exec.Source(), "name ILIKE ?")
+	}
+	if args := exec.Args(); len(args) != 1 || args[0] != "foo" {
+		t.Fatalf("Args() = %v, want [%q] (IExact is an exact case-insensitive match, no wildcard wrapping)", args, "foo")
+	}
+}
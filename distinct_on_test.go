@@ -0,0 +1,34 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package toyorm
+
+import "testing"
+
+func TestFindExecDistinctOn(t *testing.T) {
+	dia := PostgreSqlDialect{}
+	model := &Model{Name: "event"}
+	columns := []Column{fakeColumnValue{col: "id"}, fakeColumnValue{col: "user_id"}, fakeColumnValue{col: "created_at"}}
+	distinctOn := []Column{fakeColumnValue{col: "user_id"}}
+
+	exec := dia.FindExec(model, columns, nil, distinctOn)
+	want := `SELECT DISTINCT ON (user_id) id,user_id,created_at FROM "event"`
+	if exec.Source() != want {
+		t.Fatalf("Source() = %q, want %q", exec.Source(), want)
+	}
+}
+
+func TestFindExecWithoutDistinctOn(t *testing.T) {
+	dia := PostgreSqlDialect{}
+	model := &Model{Name: "event"}
+	columns := []Column{fakeColumnValue{col: "id"}}
+
+	exec := dia.FindExec(model, columns, nil, nil)
+	want := `SELECT id FROM "event"`
+	if exec.Source() != want {
+		t.Fatalf("Source() = %q, want %q", exec.Source(), want)
+	}
+}
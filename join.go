@@ -0,0 +1,176 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package toyorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// JoinType selects how a JoinInfo is rendered into the FROM clause.
+type JoinType int
+
+const (
+	InnerJoin JoinType = iota
+	LeftJoin
+)
+
+func (t JoinType) String() string {
+	if t == LeftJoin {
+		return "LEFT JOIN"
+	}
+	return "INNER JOIN"
+}
+
+// JoinInfo describes a single join for the dialect to render into the FROM
+// clause. OnLeft/OnRight are already-qualified "alias.column" strings.
+// JoinBuilder is what produces these from a relation chain; construct one
+// by hand only in tests.
+type JoinInfo struct {
+	Type    JoinType
+	Table   string
+	Alias   string
+	OnLeft  string
+	OnRight string
+}
+
+// TableAliasSet hands out T1, T2... aliases the way Beego's dbTables does,
+// one per distinct table a brick touches while building a query.
+type TableAliasSet struct {
+	aliases map[string]string
+	order   []string
+}
+
+func NewTableAliasSet() *TableAliasSet {
+	return &TableAliasSet{aliases: map[string]string{}}
+}
+
+// Alias returns the alias assigned to table, assigning the next "T<n>" the
+// first time the table is seen.
+func (s *TableAliasSet) Alias(table string) string {
+	if a, ok := s.aliases[table]; ok {
+		return a
+	}
+	a := fmt.Sprintf("T%d", len(s.order)+1)
+	s.aliases[table] = a
+	s.order = append(s.order, table)
+	return a
+}
+
+// RelationKind names the shape a relation connects its side of the join
+// with, matching the three relation kinds a ToyBrick model declares.
+type RelationKind int
+
+const (
+	BelongsTo RelationKind = iota
+	OneToOne
+	OneToMany
+)
+
+// Relation describes one hop across a BelongsTo/OneToOne/OneToMany
+// relation: the column on the model already in the query (LocalColumn) and
+// the column on RelationModel it's matched against (RelationColumn).
+type Relation struct {
+	Kind           RelationKind
+	Type           JoinType
+	RelationModel  *Model
+	LocalColumn    Column
+	RelationColumn Column
+}
+
+// JoinBuilder walks a chain of Relations rooted at a model already in the
+// FROM clause and turns it into the []JoinInfo FindExec renders and the
+// alias-qualified columns SearchExec/FindExec need to reference a joined
+// table unambiguously. This is the builder a ToyBrick Where/Preload chain
+// is meant to drive as it walks a BelongsTo/OneToOne/OneToMany relation;
+// until that brick-side call exists, construct one directly as below.
+type JoinBuilder struct {
+	root    *Model
+	aliases *TableAliasSet
+	joins   []JoinInfo
+}
+
+// NewJoinBuilder starts a join chain rooted at root, assigning it the
+// first alias (T1) so every column pulled straight off root can be
+// qualified with RootAlias.
+func NewJoinBuilder(root *Model) *JoinBuilder {
+	aliases := NewTableAliasSet()
+	aliases.Alias(root.Name)
+	return &JoinBuilder{root: root, aliases: aliases}
+}
+
+// RootAlias is the alias assigned to the model JoinBuilder was rooted at.
+func (b *JoinBuilder) RootAlias() string {
+	return b.aliases.Alias(b.root.Name)
+}
+
+// Join adds a hop across rel, joining it onto localAlias (the alias of the
+// side rel.LocalColumn belongs to — RootAlias for a relation straight off
+// the root, or a previous call's return value to chain further). It
+// returns the alias assigned to rel.RelationModel, for qualifying that
+// relation's own columns.
+func (b *JoinBuilder) Join(localAlias string, rel Relation) string {
+	relAlias := b.aliases.Alias(rel.RelationModel.Name)
+	b.joins = append(b.joins, JoinInfo{
+		Type:    rel.Type,
+		Table:   rel.RelationModel.Name,
+		Alias:   relAlias,
+		OnLeft:  columnRef(localAlias, rel.LocalColumn.Column()),
+		OnRight: columnRef(relAlias, rel.RelationColumn.Column()),
+	})
+	return relAlias
+}
+
+// Joins returns the []JoinInfo accumulated so far, in the order Join was
+// called — exactly what FindExec's joins parameter expects.
+func (b *JoinBuilder) Joins() []JoinInfo {
+	return b.joins
+}
+
+// columnRef qualifies column with alias, unless column is already
+// qualified (contains a "."), so a caller can pass an already-aliased
+// string through unchanged.
+func columnRef(alias, column string) string {
+	if alias == "" || strings.Contains(column, ".") {
+		return column
+	}
+	return alias + "." + column
+}
+
+// aliasedColumn wraps a Column so Column() reports it qualified with
+// alias ("T2.name" instead of "name") — the shape FindExec's SELECT list
+// and ORDER BY/GROUP BY columns need once a join puts more than one table
+// in the FROM clause.
+type aliasedColumn struct {
+	col   Column
+	alias string
+}
+
+func (c aliasedColumn) Column() string { return columnRef(c.alias, c.col.Column()) }
+
+// AliasColumn qualifies col with alias for rendering, the column-side half
+// of what JoinBuilder needs wired up: JoinBuilder.Join resolves which
+// alias a relation's table gets, AliasColumn is how the caller stamps that
+// alias onto the columns it selects or filters on from that relation.
+func AliasColumn(alias string, col Column) Column {
+	return aliasedColumn{col: col, alias: alias}
+}
+
+// aliasedColumnValue is AliasColumn for a ColumnValue (a WHERE operand),
+// leaving Value() untouched.
+type aliasedColumnValue struct {
+	ColumnValue
+	alias string
+}
+
+func (c aliasedColumnValue) Column() string { return columnRef(c.alias, c.ColumnValue.Column()) }
+
+// AliasColumnValue qualifies col with alias the same way AliasColumn does,
+// for use in a SearchList condition against a joined table's column.
+func AliasColumnValue(alias string, col ColumnValue) ColumnValue {
+	return aliasedColumnValue{ColumnValue: col, alias: alias}
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package toyorm
+
+import "testing"
+
+func TestTableAliasSetAssignsStableAliases(t *testing.T) {
+	set := NewTableAliasSet()
+	if a := set.Alias("user"); a != "T1" {
+		t.Fatalf("Alias(user) = %q, want T1", a)
+	}
+	if a := set.Alias("order"); a != "T2" {
+		t.Fatalf("Alias(order) = %q, want T2", a)
+	}
+	if a := set.Alias("user"); a != "T1" {
+		t.Fatalf("Alias(user) on second call = %q, want T1 (same table must keep its alias)", a)
+	}
+}
+
+func TestFindExecRendersJoinClause(t *testing.T) {
+	dia := PostgreSqlDialect{}
+	model := &Model{Name: "user"}
+	columns := []Column{fakeColumnValue{col: "T1.id"}, fakeColumnValue{col: "T2.name"}}
+	joins := []JoinInfo{{
+		Type:    InnerJoin,
+		Table:   "profile",
+		Alias:   "T2",
+		OnLeft:  "T1.profile_id",
+		OnRight: "T2.id",
+	}}
+
+	exec := dia.FindExec(model, columns, joins, nil)
+	want := `SELECT T1.id,T2.name FROM "user" T1 INNER JOIN "profile" T2 ON T1.profile_id = T2.id`
+	if exec.Source() != want {
+		t.Fatalf("Source() = %q, want %q", exec.Source(), want)
+	}
+}
+
+// TestJoinBuilderWalksRelationIntoFindExec is the wiring TestFindExecRendersJoinClause
+// couldn't prove on its own: a Relation walked through JoinBuilder, not a
+// hand-built JoinInfo, driving a real FindExec/SearchExec call.
+func TestJoinBuilderWalksRelationIntoFindExec(t *testing.T) {
+	user := &Model{Name: "user"}
+	profile := &Model{Name: "profile"}
+
+	jb := NewJoinBuilder(user)
+	rootAlias := jb.RootAlias()
+	profileAlias := jb.Join(rootAlias, Relation{
+		Kind:           BelongsTo,
+		Type:           InnerJoin,
+		RelationModel:  profile,
+		LocalColumn:    fakeColumnValue{col: "profile_id"},
+		RelationColumn: fakeColumnValue{col: "id"},
+	})
+
+	columns := []Column{
+		AliasColumn(rootAlias, fakeColumnValue{col: "id"}),
+		AliasColumn(profileAlias, fakeColumnValue{col: "name"}),
+	}
+
+	dia := PostgreSqlDialect{}
+	exec := dia.FindExec(user, columns, jb.Joins(), nil)
+	want := `SELECT T1.id,T2.name FROM "user" T1 INNER JOIN "profile" T2 ON T1.profile_id = T2.id`
+	if exec.Source() != want {
+		t.Fatalf("Source() = %q, want %q", exec.Source(), want)
+	}
+
+	search := SearchList{{Type: ExprEqual, Val: AliasColumnValue(profileAlias, fakeColumnValue{col: "name", val: "alice"})}}
+	searchExec := dia.SearchExec(search)
+	if searchExec.Source() != "T2.name = ?" {
+		t.Fatalf("Source() = %q, want %q", searchExec.Source(), "T2.name = ?")
+	}
+	if args := searchExec.Args(); len(args) != 1 || args[0] != "alice" {
+		t.Fatalf("Args() = %v, want [alice]", args)
+	}
+}
+
+func TestColumnRefLeavesAlreadyQualifiedColumnAlone(t *testing.T) {
+	if got := columnRef("T1", "name"); got != "T1.name" {
+		t.Fatalf("columnRef(T1, name) = %q, want T1.name", got)
+	}
+	if got := columnRef("T1", "T2.name"); got != "T2.name" {
+		t.Fatalf("columnRef(T1, T2.name) = %q, want T2.name (already-qualified columns pass through)", got)
+	}
+}
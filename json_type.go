@@ -0,0 +1,53 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package toyorm
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// JSONB is the sql type for a Postgres jsonb column; use it as a field's
+// sql type tag to get `jsonb` in CreateTable instead of toyorm's default
+// text fallback.
+const JSONB = "jsonb"
+
+// JSON wraps an arbitrary Go value (map[string]interface{}, a struct, a
+// slice...) so it can be written to and read back from a json/jsonb
+// column through the database/sql Valuer/Scanner interfaces.
+type JSON struct {
+	Data interface{}
+}
+
+func (j JSON) Value() (driver.Value, error) {
+	if j.Data == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(j.Data)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (j *JSON) Scan(src interface{}) error {
+	if src == nil {
+		j.Data = nil
+		return nil
+	}
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("toyorm: cannot scan %T into JSON", src)
+	}
+	return json.Unmarshal(data, &j.Data)
+}
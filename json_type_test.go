@@ -0,0 +1,59 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package toyorm
+
+import "testing"
+
+func TestSearchExecJSONHasKeyEscapesOperator(t *testing.T) {
+	dia := PostgreSqlDialect{}
+	search := SearchList{{Type: ExprJSONHasKey, Val: fakeColumnValue{col: "data", val: "color"}}}
+	exec := dia.SearchExec(search)
+
+	const want = "data ?? ?"
+	if exec.Source() != want {
+		t.Fatalf("Source() = %q, want %q", exec.Source(), want)
+	}
+	args := exec.Args()
+	if len(args) != 1 || args[0] != "color" {
+		t.Fatalf("Args() = %v, want exactly one bound arg [%q] (the literal ?? must not consume a second one)", args, "color")
+	}
+}
+
+func TestSearchExecJSONContainsAndExtract(t *testing.T) {
+	dia := PostgreSqlDialect{}
+
+	contains := dia.SearchExec(SearchList{{Type: ExprJSONContains, Val: fakeColumnValue{col: "data", val: `{"a":1}`}}})
+	if contains.Source() != "data @> ?" {
+		t.Fatalf("Source() = %q, want %q", contains.Source(), "data @> ?")
+	}
+
+	extract := dia.SearchExec(SearchList{{Type: ExprJSONExtract, Val: fakeColumnValue{col: "data", val: "{a,b}"}}})
+	if extract.Source() != "data #> ?" {
+		t.Fatalf("Source() = %q, want %q", extract.Source(), "data #> ?")
+	}
+}
+
+func TestJSONValueAndScanRoundTrip(t *testing.T) {
+	j := JSON{Data: map[string]interface{}{"a": float64(1)}}
+	v, err := j.Value()
+	if err != nil {
+		t.Fatalf("Value() error: %v", err)
+	}
+	s, ok := v.(string)
+	if !ok {
+		t.Fatalf("Value() = %T, want string", v)
+	}
+
+	var out JSON
+	if err := out.Scan(s); err != nil {
+		t.Fatalf("Scan() error: %v", err)
+	}
+	m, ok := out.Data.(map[string]interface{})
+	if !ok || m["a"] != float64(1) {
+		t.Fatalf("Scan() round-tripped to %#v, want map[a:1]", out.Data)
+	}
+}
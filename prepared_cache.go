@@ -0,0 +1,93 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package toyorm
+
+import (
+	"container/list"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"sync"
+)
+
+// DefaultPreparedCacheSize is how many prepared statements PreparedCache
+// keeps alive before evicting the least-recently-used one.
+const DefaultPreparedCacheSize = 256
+
+// PreparedCache lazily Prepares each rendered SQL string once and reuses
+// the resulting *sql.Stmt on later calls with the same (dialect, query),
+// evicting the least-recently-used entry once Size is exceeded. It is safe
+// for concurrent use. A nil or Disabled cache simply never caches.
+type PreparedCache struct {
+	Size     int
+	Disabled bool
+
+	mu    sync.Mutex
+	items map[string]*list.Element
+	order *list.List
+}
+
+type preparedCacheEntry struct {
+	key  string
+	stmt *sql.Stmt
+}
+
+func NewPreparedCache() *PreparedCache {
+	return &PreparedCache{
+		Size:  DefaultPreparedCacheSize,
+		items: map[string]*list.Element{},
+		order: list.New(),
+	}
+}
+
+func preparedCacheKey(dialect, query string) string {
+	sum := sha1.Sum([]byte(query))
+	return dialect + ":" + hex.EncodeToString(sum[:])
+}
+
+// Prepare returns a cached *sql.Stmt for (dialect, query), Preparing and
+// storing a new one on db the first time the query is seen. It bypasses
+// the cache entirely for a *sql.Tx, since a transaction's statements do
+// not outlive it, and returns (nil, nil) whenever caching doesn't apply so
+// callers fall back to db.Exec/db.QueryRow with the plain query string.
+func (c *PreparedCache) Prepare(db Executor, dialect, query string) (*sql.Stmt, error) {
+	if c == nil || c.Disabled {
+		return nil, nil
+	}
+	sqlDB, ok := db.(*sql.DB)
+	if !ok {
+		return nil, nil
+	}
+	key := preparedCacheKey(dialect, query)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.order.MoveToFront(elem)
+		return elem.Value.(*preparedCacheEntry).stmt, nil
+	}
+
+	stmt, err := sqlDB.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	elem := c.order.PushFront(&preparedCacheEntry{key: key, stmt: stmt})
+	c.items[key] = elem
+	if size := c.Size; size > 0 {
+		for c.order.Len() > size {
+			oldest := c.order.Back()
+			if oldest == nil {
+				break
+			}
+			entry := oldest.Value.(*preparedCacheEntry)
+			entry.stmt.Close()
+			delete(c.items, entry.key)
+			c.order.Remove(oldest)
+		}
+	}
+	return stmt, nil
+}
@@ -0,0 +1,180 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package toyorm
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+)
+
+// countingDriver is a bare-bones database/sql driver whose only job is to
+// count how many times Prepare is actually called on the connection, so
+// PreparedCache's reuse behavior can be asserted without a real Postgres.
+type countingDriver struct{}
+
+var driverCounters = struct {
+	mu sync.Mutex
+	m  map[string]*int
+}{m: map[string]*int{}}
+
+func (countingDriver) Open(name string) (driver.Conn, error) {
+	driverCounters.mu.Lock()
+	counter := driverCounters.m[name]
+	driverCounters.mu.Unlock()
+	return countingConn{prepareCount: counter}, nil
+}
+
+var registerCountingDriverOnce sync.Once
+
+func registerCountingDriver() {
+	sql.Register("toyorm-counting-driver", countingDriver{})
+}
+
+type countingConn struct {
+	prepareCount *int
+}
+
+func (c countingConn) Prepare(query string) (driver.Stmt, error) {
+	*c.prepareCount++
+	return countingStmt{}, nil
+}
+func (c countingConn) Close() error              { return nil }
+func (c countingConn) Begin() (driver.Tx, error) { return countingTx{}, nil }
+
+type countingTx struct{}
+
+func (countingTx) Commit() error   { return nil }
+func (countingTx) Rollback() error { return nil }
+
+type countingStmt struct{}
+
+func (countingStmt) Close() error  { return nil }
+func (countingStmt) NumInput() int { return -1 }
+func (countingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+func (countingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &countingRows{}, nil
+}
+
+type countingRows struct {
+	done bool
+}
+
+func (r *countingRows) Columns() []string { return []string{"id"} }
+func (r *countingRows) Close() error      { return nil }
+func (r *countingRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(42)
+	return nil
+}
+
+func TestInsertExecutorReusesPreparedStatement(t *testing.T) {
+	registerCountingDriverOnce.Do(registerCountingDriver)
+
+	counter := 0
+	dsn := "TestInsertExecutorReusesPreparedStatement"
+	driverCounters.mu.Lock()
+	driverCounters.m[dsn] = &counter
+	driverCounters.mu.Unlock()
+
+	db, err := sql.Open("toyorm-counting-driver", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db.Close()
+
+	dia := PostgreSqlDialect{}
+	model := &Model{Name: "user"}
+	cache := NewPreparedCache()
+	debug := func(string, string, error) {}
+
+	for i := 0; i < 3; i++ {
+		cvs := []ColumnValue{fakeColumnValue{col: "name", val: "alice"}}
+		exec, returning := dia.InsertExec(model, cvs, nil)
+		if _, err := dia.InsertExecutor(db, cache, exec, returning, debug); err != nil {
+			t.Fatalf("InsertExecutor: %v", err)
+		}
+	}
+
+	if counter != 1 {
+		t.Fatalf("connection Prepare called %d times, want 1 (later inserts should reuse the cached *sql.Stmt)", counter)
+	}
+}
+
+func TestInsertExecutorWithNilCacheStillWorks(t *testing.T) {
+	registerCountingDriverOnce.Do(registerCountingDriver)
+
+	counter := 0
+	dsn := "TestInsertExecutorWithNilCacheStillWorks"
+	driverCounters.mu.Lock()
+	driverCounters.m[dsn] = &counter
+	driverCounters.mu.Unlock()
+
+	db, err := sql.Open("toyorm-counting-driver", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db.Close()
+
+	dia := PostgreSqlDialect{}
+	model := &Model{Name: "user"}
+	cvs := []ColumnValue{fakeColumnValue{col: "name", val: "alice"}}
+	exec, returning := dia.InsertExec(model, cvs, nil)
+
+	if _, err := dia.InsertExecutor(db, nil, exec, returning, debugNoop); err != nil {
+		t.Fatalf("InsertExecutor with nil cache: %v", err)
+	}
+}
+
+func debugNoop(string, string, error) {}
+
+// TestInsertExecReturningFlowsToExecutor guards against InsertExec
+// resolving a RETURNING clause (here from an explicit returning list)
+// that the caller then fails to pass on to InsertExecutor: if the two
+// disagree about whether RETURNING is in the query, InsertExecutor picks
+// db.Exec over db.QueryRow and the returned row is silently dropped.
+func TestInsertExecReturningFlowsToExecutor(t *testing.T) {
+	registerCountingDriverOnce.Do(registerCountingDriver)
+
+	counter := 0
+	dsn := "TestInsertExecReturningFlowsToExecutor"
+	driverCounters.mu.Lock()
+	driverCounters.m[dsn] = &counter
+	driverCounters.mu.Unlock()
+
+	db, err := sql.Open("toyorm-counting-driver", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db.Close()
+
+	dia := PostgreSqlDialect{}
+	model := &Model{Name: "user"}
+	cvs := []ColumnValue{fakeColumnValue{col: "name", val: "alice"}}
+	requested := []Column{fakeColumnValue{col: "id"}}
+
+	exec, resolved := dia.InsertExec(model, cvs, requested)
+	if len(resolved) != 1 || resolved[0].Column() != "id" {
+		t.Fatalf("InsertExec resolved returning = %v, want [id]", resolved)
+	}
+
+	res, err := dia.InsertExecutor(db, nil, exec, resolved, debugNoop)
+	if err != nil {
+		t.Fatalf("InsertExecutor: %v", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil || id != 42 {
+		t.Fatalf("LastInsertId() = (%d, %v), want (42, nil); the RETURNING row must actually be scanned, not dropped", id, err)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package toyorm
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+)
+
+// multiIntDriver fakes a RETURNING row with more than one int64 column, in
+// a fixed order, so LastInsertId can be checked against map-iteration-order
+// flakiness: with a plain map scan this would pick whichever of the two
+// int64 columns Go's randomized map order happened to visit first.
+type multiIntDriver struct{}
+
+func (multiIntDriver) Open(name string) (driver.Conn, error) {
+	return multiIntConn{}, nil
+}
+
+var registerMultiIntDriverOnce sync.Once
+
+func registerMultiIntDriver() {
+	sql.Register("toyorm-multi-int-driver", multiIntDriver{})
+}
+
+type multiIntConn struct{}
+
+func (multiIntConn) Prepare(query string) (driver.Stmt, error) { return multiIntStmt{}, nil }
+func (multiIntConn) Close() error                              { return nil }
+func (multiIntConn) Begin() (driver.Tx, error)                 { return countingTx{}, nil }
+
+type multiIntStmt struct{}
+
+func (multiIntStmt) Close() error  { return nil }
+func (multiIntStmt) NumInput() int { return -1 }
+func (multiIntStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+func (multiIntStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &multiIntRows{}, nil
+}
+
+// multiIntRows returns two int64 columns, "id" (7) and "version" (99), so a
+// correct LastInsertId must pick "id" because that's the column requested
+// first, not because it happens to win some map ordering.
+type multiIntRows struct {
+	done bool
+}
+
+func (r *multiIntRows) Columns() []string { return []string{"id", "version"} }
+func (r *multiIntRows) Close() error      { return nil }
+func (r *multiIntRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(7)
+	dest[1] = int64(99)
+	return nil
+}
+
+// TestLastInsertIdPicksFirstRequestedColumn guards against ReturningResult
+// picking "the first int64-valued column" by ranging over its Values map,
+// whose iteration order Go leaves unspecified: with two int64 columns in
+// RETURNING, that can silently return either one on different runs.
+func TestLastInsertIdPicksFirstRequestedColumn(t *testing.T) {
+	registerMultiIntDriverOnce.Do(registerMultiIntDriver)
+
+	db, err := sql.Open("toyorm-multi-int-driver", "TestLastInsertIdPicksFirstRequestedColumn")
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db.Close()
+
+	dia := PostgreSqlDialect{}
+	model := &Model{Name: "user"}
+	cvs := []ColumnValue{fakeColumnValue{col: "name", val: "alice"}}
+	requested := []Column{fakeColumnValue{col: "id"}, fakeColumnValue{col: "version"}}
+
+	for i := 0; i < 20; i++ {
+		exec, resolved := dia.InsertExec(model, cvs, requested)
+		res, err := dia.InsertExecutor(db, nil, exec, resolved, debugNoop)
+		if err != nil {
+			t.Fatalf("InsertExecutor: %v", err)
+		}
+		id, err := res.LastInsertId()
+		if err != nil || id != 7 {
+			t.Fatalf("LastInsertId() = (%d, %v), want (7, nil); must always pick the first-requested RETURNING column (id), not whichever int64 map iteration visits first", id, err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package toyorm
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// wrappedColumnValue overrides Value() on an existing ColumnValue so a
+// search-node constructor can rewrite the bound value (e.g. add LIKE
+// wildcards) without touching what Column() reports.
+type wrappedColumnValue struct {
+	ColumnValue
+	wrapped interface{}
+}
+
+func (w wrappedColumnValue) Value() reflect.Value {
+	return reflect.ValueOf(w.wrapped)
+}
+
+// ColumnContains, ColumnStartsWith and ColumnEndsWith build the SearchList
+// node for their Expr, wrapping the raw substring with the % wildcards a
+// LIKE/ILIKE match needs right here, once, so every dialect's SearchExec
+// can render Contains/StartsWith/EndsWith identically to a plain LIKE
+// instead of each reimplementing (and risking mismatching) the wrapping.
+func ColumnContains(col ColumnValue) SearchList {
+	return SearchList{{Type: ExprContains, Val: wrappedColumnValue{col, fmt.Sprintf("%%%v%%", col.Value().Interface())}}}
+}
+
+func ColumnIContains(col ColumnValue) SearchList {
+	return SearchList{{Type: ExprIContains, Val: wrappedColumnValue{col, fmt.Sprintf("%%%v%%", col.Value().Interface())}}}
+}
+
+func ColumnStartsWith(col ColumnValue) SearchList {
+	return SearchList{{Type: ExprStartsWith, Val: wrappedColumnValue{col, fmt.Sprintf("%v%%", col.Value().Interface())}}}
+}
+
+func ColumnIStartsWith(col ColumnValue) SearchList {
+	return SearchList{{Type: ExprIStartsWith, Val: wrappedColumnValue{col, fmt.Sprintf("%v%%", col.Value().Interface())}}}
+}
+
+func ColumnEndsWith(col ColumnValue) SearchList {
+	return SearchList{{Type: ExprEndsWith, Val: wrappedColumnValue{col, fmt.Sprintf("%%%v", col.Value().Interface())}}}
+}
+
+func ColumnIEndsWith(col ColumnValue) SearchList {
+	return SearchList{{Type: ExprIEndsWith, Val: wrappedColumnValue{col, fmt.Sprintf("%%%v", col.Value().Interface())}}}
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package toyorm
+
+import "testing"
+
+func TestColumnLikeFamilyBuildersWrapValue(t *testing.T) {
+	cases := []struct {
+		name    string
+		build   func(ColumnValue) SearchList
+		expr    int
+		wantVal string
+	}{
+		{"contains", ColumnContains, ExprContains, "%foo%"},
+		{"icontains", ColumnIContains, ExprIContains, "%foo%"},
+		{"starts with", ColumnStartsWith, ExprStartsWith, "foo%"},
+		{"istarts with", ColumnIStartsWith, ExprIStartsWith, "foo%"},
+		{"ends with", ColumnEndsWith, ExprEndsWith, "%foo"},
+		{"iends with", ColumnIEndsWith, ExprIEndsWith, "%foo"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			search := c.build(fakeColumnValue{col: "name", val: "foo"})
+			if len(search) != 1 || search[0].Type != c.expr {
+				t.Fatalf("search = %+v, want a single node of type %d", search, c.expr)
+			}
+			if search[0].Val.Column() != "name" {
+				t.Fatalf("Column() = %q, want %q (wrapping must not disturb the column name)", search[0].Val.Column(), "name")
+			}
+			if got := search[0].Val.Value().Interface(); got != c.wantVal {
+				t.Fatalf("Value() = %v, want %q", got, c.wantVal)
+			}
+		})
+	}
+}
+
+// TestColumnLikeFamilyEndToEnd confirms the wrapping built once here survives
+// unchanged through PostgreSqlDialect.SearchExec, so every dialect shares the
+// exact same Contains/StartsWith/EndsWith behavior instead of each
+// reimplementing the % placement itself.
+func TestColumnLikeFamilyEndToEnd(t *testing.T) {
+	dia := PostgreSqlDialect{}
+	search := ColumnContains(fakeColumnValue{col: "name", val: "foo"})
+	exec := dia.SearchExec(search)
+	if exec.Source() != "name LIKE ?" {
+		t.Fatalf("Source() = %q, want %q", exec.Source(), "name LIKE ?")
+	}
+	if args := exec.Args(); len(args) != 1 || args[0] != "%foo%" {
+		t.Fatalf("Args() = %v, want [%q]", args, "%foo%")
+	}
+}
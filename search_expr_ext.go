@@ -0,0 +1,31 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package toyorm
+
+// string-match search expressions beyond the basic LIKE/NOT LIKE pair,
+// covering the Beego-style ILIKE/contains/starts-with/regex vocabulary.
+// The leading value is offset well clear of the existing Expr block so it
+// never collides with it.
+const (
+	ExprIExact = iota + 1000
+	ExprContains
+	ExprIContains
+	ExprStartsWith
+	ExprIStartsWith
+	ExprEndsWith
+	ExprIEndsWith
+	ExprRegex
+	ExprIRegex
+)
+
+// jsonb search expressions: containment, key existence and path extraction.
+// Postgres-only; other dialects have no text-column equivalent.
+const (
+	ExprJSONContains = iota + 1100
+	ExprJSONHasKey
+	ExprJSONExtract
+)